@@ -0,0 +1,41 @@
+package httpgrpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protoV2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/protoadapt"
+)
+
+// AsProtoV2 adapts one of this package's hand-maintained, legacy-style
+// (Reset/String/ProtoMessage) messages onto the google.golang.org/protobuf
+// v2 proto.Message interface that DetailRegistry and other
+// google.golang.org/protobuf v2 APIs require. Those types deliberately don't implement
+// ProtoReflect themselves: protoadapt.MessageV2Of short-circuits and returns
+// its argument unchanged whenever it already implements
+// protoreflect.ProtoMessage, so a ProtoReflect method defined in terms of
+// this same adapter would recurse into itself instead of reaching the
+// legacy-reflection fallback that actually builds one.
+func AsProtoV2(m protoadapt.MessageV1) protoV2.Message {
+	return protoadapt.MessageV2Of(m)
+}
+
+// ErrorFromHTTPResponse converts an HTTPResponse into a gRPC error, stuffing
+// the HTTP status code directly into the gRPC status code so HTTPResponseFromError
+// can recover it on the other end without needing status details.
+func ErrorFromHTTPResponse(resp *HTTPResponse) error {
+	return status.New(codes.Code(resp.Code), string(resp.Body)).Err()
+}
+
+// HTTPResponseFromError recovers the HTTPResponse embedded in err by
+// ErrorFromHTTPResponse, if any.
+func HTTPResponseFromError(err error) (*HTTPResponse, bool) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	return &HTTPResponse{
+		Code: int32(s.Code()),
+		Body: []byte(s.Message()),
+	}, true
+}
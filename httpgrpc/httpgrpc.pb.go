@@ -0,0 +1,281 @@
+// This file is hand-maintained in the structure of protoc-gen-go v1
+// (github.com/golang/protobuf/protoc-gen-go) output for httpgrpc.proto: it
+// has not actually been run through protoc, so regenerating it from the
+// .proto in this directory is not a no-op. Every type here only implements
+// the legacy Reset/String/ProtoMessage interface (plus `protobuf:"..."`
+// struct tags) rather than defining its own ProtoReflect method; that's
+// deliberate, not an oversight — see AsProtoV2 in httpgrpc.go for why.
+// source: httpgrpc.proto
+
+package httpgrpc
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Header represents an HTTP header's key and (possibly multiple) values.
+type Header struct {
+	Key    string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Values []string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *Header) Reset()         { *m = Header{} }
+func (m *Header) String() string { return proto.CompactTextString(m) }
+func (*Header) ProtoMessage()    {}
+
+func (m *Header) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Header) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// HTTPRequest carries everything needed to replay an http.Request on the
+// other end of the wire, for request bodies small enough to fit in a single
+// message; see HandleStream for larger ones.
+type HTTPRequest struct {
+	Method  string    `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Url     string    `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Headers []*Header `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty"`
+	Body    []byte    `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *HTTPRequest) Reset()         { *m = HTTPRequest{} }
+func (m *HTTPRequest) String() string { return proto.CompactTextString(m) }
+func (*HTTPRequest) ProtoMessage()    {}
+
+func (m *HTTPRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *HTTPRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *HTTPRequest) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *HTTPRequest) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+// HTTPResponse carries everything needed to replay an http.Response on the
+// other end of the wire, for response bodies small enough to fit in a
+// single message; see HandleStream for larger ones.
+type HTTPResponse struct {
+	Code    int32     `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Headers []*Header `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
+	Body    []byte    `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (m *HTTPResponse) Reset()         { *m = HTTPResponse{} }
+func (m *HTTPResponse) String() string { return proto.CompactTextString(m) }
+func (*HTTPResponse) ProtoMessage()    {}
+
+func (m *HTTPResponse) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *HTTPResponse) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *HTTPResponse) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+// RequestMetadata is the first message a HandleStream caller sends: the
+// HTTPRequest fields that aren't the body, which streams afterwards as a
+// series of BodyChunks.
+type RequestMetadata struct {
+	Method  string    `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Url     string    `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Headers []*Header `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty"`
+}
+
+func (m *RequestMetadata) Reset()         { *m = RequestMetadata{} }
+func (m *RequestMetadata) String() string { return proto.CompactTextString(m) }
+func (*RequestMetadata) ProtoMessage()    {}
+
+func (m *RequestMetadata) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *RequestMetadata) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *RequestMetadata) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// ResponseMetadata is the first message HandleStream replies with: the
+// HTTPResponse fields that aren't the body, which streams afterwards as a
+// series of BodyChunks.
+type ResponseMetadata struct {
+	Code    int32     `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Headers []*Header `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
+}
+
+func (m *ResponseMetadata) Reset()         { *m = ResponseMetadata{} }
+func (m *ResponseMetadata) String() string { return proto.CompactTextString(m) }
+func (*ResponseMetadata) ProtoMessage()    {}
+
+func (m *ResponseMetadata) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *ResponseMetadata) GetHeaders() []*Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+// BodyChunk carries a slice of a streamed request or response body. Eof
+// marks the final chunk; Data may be empty on that final chunk.
+type BodyChunk struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Eof  bool   `protobuf:"varint,2,opt,name=eof,proto3" json:"eof,omitempty"`
+}
+
+func (m *BodyChunk) Reset()         { *m = BodyChunk{} }
+func (m *BodyChunk) String() string { return proto.CompactTextString(m) }
+func (*BodyChunk) ProtoMessage()    {}
+
+func (m *BodyChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *BodyChunk) GetEof() bool {
+	if m != nil {
+		return m.Eof
+	}
+	return false
+}
+
+// DoNotLogFlag is a zero-field marker: its mere presence among a Status'
+// Details means the error it's attached to should be suppressed from logs.
+type DoNotLogFlag struct{}
+
+func (m *DoNotLogFlag) Reset()         { *m = DoNotLogFlag{} }
+func (m *DoNotLogFlag) String() string { return proto.CompactTextString(m) }
+func (*DoNotLogFlag) ProtoMessage()    {}
+
+// HTTPStreamChunk is the single message type HandleStream exchanges in both
+// directions; exactly one of the oneof fields is set per message, with
+// metadata always sent first and chunks following.
+type HTTPStreamChunk struct {
+	// Types that are valid to be assigned to Message:
+	//	*HTTPStreamChunk_RequestMetadata
+	//	*HTTPStreamChunk_ResponseMetadata
+	//	*HTTPStreamChunk_Chunk
+	Message isHTTPStreamChunk_Message `protobuf_oneof:"message"`
+}
+
+func (m *HTTPStreamChunk) Reset()         { *m = HTTPStreamChunk{} }
+func (m *HTTPStreamChunk) String() string { return proto.CompactTextString(m) }
+func (*HTTPStreamChunk) ProtoMessage()    {}
+
+type isHTTPStreamChunk_Message interface {
+	isHTTPStreamChunk_Message()
+}
+
+type HTTPStreamChunk_RequestMetadata struct {
+	RequestMetadata *RequestMetadata `protobuf:"bytes,1,opt,name=request_metadata,json=requestMetadata,proto3,oneof"`
+}
+
+type HTTPStreamChunk_ResponseMetadata struct {
+	ResponseMetadata *ResponseMetadata `protobuf:"bytes,2,opt,name=response_metadata,json=responseMetadata,proto3,oneof"`
+}
+
+type HTTPStreamChunk_Chunk struct {
+	Chunk *BodyChunk `protobuf:"bytes,3,opt,name=chunk,proto3,oneof"`
+}
+
+func (*HTTPStreamChunk_RequestMetadata) isHTTPStreamChunk_Message()  {}
+func (*HTTPStreamChunk_ResponseMetadata) isHTTPStreamChunk_Message() {}
+func (*HTTPStreamChunk_Chunk) isHTTPStreamChunk_Message()            {}
+
+func (m *HTTPStreamChunk) GetMessage() isHTTPStreamChunk_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (m *HTTPStreamChunk) GetRequestMetadata() *RequestMetadata {
+	if x, ok := m.GetMessage().(*HTTPStreamChunk_RequestMetadata); ok {
+		return x.RequestMetadata
+	}
+	return nil
+}
+
+func (m *HTTPStreamChunk) GetResponseMetadata() *ResponseMetadata {
+	if x, ok := m.GetMessage().(*HTTPStreamChunk_ResponseMetadata); ok {
+		return x.ResponseMetadata
+	}
+	return nil
+}
+
+func (m *HTTPStreamChunk) GetChunk() *BodyChunk {
+	if x, ok := m.GetMessage().(*HTTPStreamChunk_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Header)(nil), "httpgrpc.Header")
+	proto.RegisterType((*HTTPRequest)(nil), "httpgrpc.HTTPRequest")
+	proto.RegisterType((*HTTPResponse)(nil), "httpgrpc.HTTPResponse")
+	proto.RegisterType((*RequestMetadata)(nil), "httpgrpc.RequestMetadata")
+	proto.RegisterType((*ResponseMetadata)(nil), "httpgrpc.ResponseMetadata")
+	proto.RegisterType((*BodyChunk)(nil), "httpgrpc.BodyChunk")
+	proto.RegisterType((*DoNotLogFlag)(nil), "httpgrpc.DoNotLogFlag")
+	proto.RegisterType((*HTTPStreamChunk)(nil), "httpgrpc.HTTPStreamChunk")
+}
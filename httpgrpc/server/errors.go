@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+)
+
+// Well-known error kinds that callers can check for with errors.Is against
+// whatever Client returns, instead of parsing response bodies or headers.
+var (
+	// ErrRetryable marks errors the caller can safely retry, derived from a
+	// Retry-After response header.
+	ErrRetryable = errors.New("httpgrpc: retryable error")
+	// ErrDoNotLog marks errors that middleware should suppress from logs.
+	// Server.Handle still derives this from the DoNotLogErrorHeaderKey
+	// response header, since that's the only channel an application
+	// http.Handler has to signal it; ErrorDetailsUnaryServerInterceptor
+	// carries the resulting fact across the wire as a status detail so a
+	// remote caller can still observe it via errors.Is without seeing HTTP
+	// headers at all.
+	ErrDoNotLog = errors.New("httpgrpc: do-not-log error")
+	// ErrClientCanceled marks errors caused by the caller canceling the
+	// request's context.
+	ErrClientCanceled = errors.New("httpgrpc: client canceled request")
+)
+
+// DetailRegistry lets callers register extra proto.Message types that should
+// be packed into/unpacked from a gRPC Status' Details, alongside the
+// well-known ones httpgrpc.server always handles.
+type DetailRegistry struct {
+	types map[string]func() proto.Message
+}
+
+// NewDetailRegistry returns an empty DetailRegistry.
+func NewDetailRegistry() *DetailRegistry {
+	return &DetailRegistry{types: map[string]func() proto.Message{}}
+}
+
+// Register adds msgType to the registry, keyed by its proto message name.
+// newMsg must return a new, empty instance of that same type.
+func (r *DetailRegistry) Register(msgType proto.Message, newMsg func() proto.Message) {
+	r.types[string(msgType.ProtoReflect().Descriptor().FullName())] = newMsg
+}
+
+// DefaultDetailRegistry is used by ErrorDetailsUnaryClientInterceptor when no
+// request-specific registry is supplied.
+var DefaultDetailRegistry = NewDetailRegistry()
+
+// HTTPResponseError is a rich error unpacked from a gRPC Status' Details. It
+// carries the original HTTP response (if any), the retry hint and
+// do-not-log flag carried in the status details, and preserves the original
+// gRPC code for errors.Is/As and status.Code(err) callers.
+type HTTPResponseError struct {
+	resp       *httpgrpc.HTTPResponse
+	code       codes.Code
+	message    string
+	retryable  bool
+	doNotLog   bool
+	custom     map[string]proto.Message
+	underlying error
+}
+
+// Detail returns the registered custom detail of the given message's type
+// packed onto this error, if any, copying it into msg.
+func (e *HTTPResponseError) Detail(msg proto.Message) bool {
+	found, ok := e.custom[string(msg.ProtoReflect().Descriptor().FullName())]
+	if !ok {
+		return false
+	}
+	proto.Merge(msg, found)
+	return true
+}
+
+func (e *HTTPResponseError) Error() string {
+	return e.message
+}
+
+// Unwrap lets errors.Is/As reach the underlying gRPC status error.
+func (e *HTTPResponseError) Unwrap() error {
+	return e.underlying
+}
+
+// Is implements errors.Is for the well-known error kinds and for
+// context.Canceled, so callers can write errors.Is(err, ErrRetryable) etc.
+func (e *HTTPResponseError) Is(target error) bool {
+	switch target { //nolint:errorlint
+	case ErrRetryable:
+		return e.retryable
+	case ErrDoNotLog:
+		return e.doNotLog
+	case ErrClientCanceled, context.Canceled:
+		return e.code == codes.Canceled
+	}
+	return false
+}
+
+// GRPCStatus lets status.FromError(err) recover the original gRPC status,
+// including its Code and Details, from a *HTTPResponseError.
+func (e *HTTPResponseError) GRPCStatus() *status.Status {
+	if st, ok := status.FromError(e.underlying); ok && st.Code() == e.code {
+		return st
+	}
+	return status.New(e.code, e.message)
+}
+
+// HTTPResponse returns the wrapped HTTP response, if the error carried one.
+// This mirrors httpgrpc.HTTPResponseFromError for rich errors produced by
+// ErrorDetailsUnaryClientInterceptor.
+func (e *HTTPResponseError) HTTPResponse() (*httpgrpc.HTTPResponse, bool) {
+	return e.resp, e.resp != nil
+}
+
+// ErrorDetailsUnaryServerInterceptor packs structured detail messages onto
+// any error a handler returns: the HTTPResponse itself (so clients keep
+// working with httpgrpc.HTTPResponseFromError), a RetryInfo derived from a
+// Retry-After header, and a do-not-log flag derived from
+// middleware.DoNotLogError (which Server.Handle already produces from the
+// DoNotLogErrorHeaderKey response header), so that fact survives the trip
+// across the wire as a status detail instead of being lost the moment the
+// error leaves this process. Extra detail types are packed too, if the
+// handler's error exposes them via an `ErrorDetails() []proto.Message`
+// method. NewGRPCServer chains this interceptor in automatically.
+func ErrorDetailsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+		if !ok {
+			return resp, err
+		}
+
+		doNotLog := errors.As(err, &middleware.DoNotLogError{})
+		st := status.Convert(err)
+		details := []proto.Message{httpgrpc.AsProtoV2(httpResp)}
+
+		if delay, ok := parseRetryAfter(firstHeaderValue(httpResp.Headers, "Retry-After")); ok {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+		}
+		if doNotLog {
+			details = append(details, httpgrpc.AsProtoV2(&httpgrpc.DoNotLogFlag{}))
+		}
+		if de, ok := err.(interface{ ErrorDetails() []proto.Message }); ok {
+			details = append(details, de.ErrorDetails()...)
+		}
+
+		// status.WithDetails takes legacy (v1) messages.
+		v1Details := make([]protoadapt.MessageV1, 0, len(details))
+		for _, d := range details {
+			v1Details = append(v1Details, protoadapt.MessageV1Of(d))
+		}
+		withDetails, detailErr := st.WithDetails(v1Details...)
+		if detailErr != nil {
+			return resp, err
+		}
+		return resp, withDetails.Err()
+	}
+}
+
+// ErrorDetailsUnaryClientInterceptor unpacks the details packed by
+// ErrorDetailsUnaryServerInterceptor into a *HTTPResponseError, so callers
+// can use errors.Is/As instead of re-parsing response bodies or headers.
+func ErrorDetailsUnaryClientInterceptor(registry *DetailRegistry) grpc.UnaryClientInterceptor {
+	if registry == nil {
+		registry = DefaultDetailRegistry
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() == context.Canceled {
+			return &HTTPResponseError{code: codes.Canceled, message: err.Error(), underlying: err}
+		}
+
+		st, ok := status.FromError(err)
+		if !ok {
+			return err
+		}
+
+		rich := &HTTPResponseError{
+			code:       st.Code(),
+			message:    st.Message(),
+			underlying: err,
+		}
+		for _, d := range st.Details() {
+			// Legacy types such as *httpgrpc.HTTPResponse come back from
+			// Details() wrapped; MessageV1Of unwraps them to the original type.
+			if m, ok := d.(proto.Message); ok {
+				d = protoadapt.MessageV1Of(m)
+			}
+			switch detail := d.(type) {
+			case *httpgrpc.HTTPResponse:
+				rich.resp = detail
+			case *errdetails.RetryInfo:
+				rich.retryable = true
+			case *httpgrpc.DoNotLogFlag:
+				rich.doNotLog = true
+			default:
+				msg, ok := d.(proto.Message)
+				if !ok {
+					continue
+				}
+				name := string(msg.ProtoReflect().Descriptor().FullName())
+				if _, known := registry.types[name]; known {
+					if rich.custom == nil {
+						rich.custom = map[string]proto.Message{}
+					}
+					rich.custom[name] = msg
+				}
+			}
+		}
+		return rich
+	}
+}
+
+func firstHeaderValue(headers []*httpgrpc.Header, key string) string {
+	for _, h := range headers {
+		if http.CanonicalHeaderKey(h.Key) == http.CanonicalHeaderKey(key) && len(h.Values) > 0 {
+			return h.Values[0]
+		}
+	}
+	return ""
+}
+
+// parseRetryAfter understands the numeric-seconds form of Retry-After; the
+// HTTP-date form is intentionally left to callers that need it, since
+// rewriting it back into a delay here would lose the original timestamp.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
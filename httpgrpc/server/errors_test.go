@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"not-a-number", 0, false},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+	}
+	for _, c := range cases {
+		got, ok := parseRetryAfter(c.value)
+		if ok != c.ok || got != c.want {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", c.value, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFirstHeaderValue(t *testing.T) {
+	headers := []*httpgrpc.Header{
+		{Key: "Retry-After", Values: []string{"5", "10"}},
+	}
+	if got := firstHeaderValue(headers, "retry-after"); got != "5" {
+		t.Errorf("firstHeaderValue = %q, want %q", got, "5")
+	}
+	if got := firstHeaderValue(headers, "X-Missing"); got != "" {
+		t.Errorf("firstHeaderValue for missing header = %q, want empty", got)
+	}
+}
+
+func TestHTTPResponseErrorIs(t *testing.T) {
+	retryable := &HTTPResponseError{retryable: true}
+	if !retryable.Is(ErrRetryable) {
+		t.Error("expected retryable error to match ErrRetryable")
+	}
+	if retryable.Is(ErrDoNotLog) {
+		t.Error("retryable error should not match ErrDoNotLog")
+	}
+
+	doNotLog := &HTTPResponseError{doNotLog: true}
+	if !doNotLog.Is(ErrDoNotLog) {
+		t.Error("expected do-not-log error to match ErrDoNotLog")
+	}
+}
+
+// TestErrorDetailsRoundTrip drives ErrorDetailsUnaryServerInterceptor and
+// ErrorDetailsUnaryClientInterceptor back-to-back against a real
+// status.WithDetails/status.FromError round trip (no actual gRPC
+// connection needed, since that's the only part of the path that touches
+// the wire). This is the path that packs an *httpgrpc.HTTPResponse,
+// *errdetails.RetryInfo and *httpgrpc.DoNotLogFlag into a Status' Details
+// and unpacks them again, so it exercises every ProtoReflect call the
+// marshal/unmarshal side of that actually makes.
+func TestErrorDetailsRoundTrip(t *testing.T) {
+	resp := &httpgrpc.HTTPResponse{
+		Code:    http.StatusInternalServerError,
+		Headers: []*httpgrpc.Header{{Key: "Retry-After", Values: []string{"5"}}},
+		Body:    []byte("boom"),
+	}
+	handlerErr := middleware.DoNotLogError{Err: httpgrpc.ErrorFromHTTPResponse(resp)}
+
+	serverInterceptor := ErrorDetailsUnaryServerInterceptor()
+	_, serverErr := serverInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+	if serverErr == nil {
+		t.Fatal("expected an error out of the server interceptor")
+	}
+
+	clientInterceptor := ErrorDetailsUnaryClientInterceptor(nil)
+	clientErr := clientInterceptor(context.Background(), "/httpgrpc.HTTP/Handle", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return serverErr
+		})
+
+	var rich *HTTPResponseError
+	if !errors.As(clientErr, &rich) {
+		t.Fatalf("expected a *HTTPResponseError, got %T: %v", clientErr, clientErr)
+	}
+	gotResp, ok := rich.HTTPResponse()
+	if !ok || gotResp.Code != resp.Code || string(gotResp.Body) != string(resp.Body) {
+		t.Errorf("HTTPResponse() = %+v, %v, want %+v, true", gotResp, ok, resp)
+	}
+	if !rich.Is(ErrRetryable) {
+		t.Error("expected the round-tripped error to match ErrRetryable")
+	}
+	if !rich.Is(ErrDoNotLog) {
+		t.Error("expected the round-tripped error to match ErrDoNotLog")
+	}
+}
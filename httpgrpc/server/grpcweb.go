@@ -0,0 +1,329 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/dskit/httpgrpc"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// grpcWebTrailerFlag marks a grpc-web frame as carrying trailers
+	// (encoded as a header block) rather than a message.
+	grpcWebTrailerFlag = 0x80
+)
+
+// grpcWebConfig collects the options applied by GRPCWebOptions.
+type grpcWebConfig struct {
+	corsAllowedOrigin func(origin string) bool
+}
+
+// GRPCWebOption configures the handler returned by NewGRPCWebHandler.
+type GRPCWebOption func(*grpcWebConfig)
+
+// WithGRPCWebCORS enables CORS for the grpc-web handler: requests whose
+// Origin header satisfies allowed get the Access-Control-* response headers
+// needed for browsers to accept the response, and OPTIONS preflight
+// requests are answered directly.
+func WithGRPCWebCORS(allowed func(origin string) bool) GRPCWebOption {
+	return func(c *grpcWebConfig) { c.corsAllowedOrigin = allowed }
+}
+
+// NewGRPCWebHandler adapts server to speak application/grpc-web and
+// application/grpc-web-text directly over HTTP/1.1 or HTTP/2, so browser and
+// edge clients that can't rely on HTTP/2 trailers can reach it without a
+// separate grpcwebproxy in front.
+func NewGRPCWebHandler(server *Server, opts ...GRPCWebOption) http.Handler {
+	cfg := &grpcWebConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &grpcWebHandler{server: server, cfg: cfg}
+}
+
+type grpcWebHandler struct {
+	server *Server
+	cfg    *grpcWebConfig
+}
+
+func (h *grpcWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.cfg.corsAllowedOrigin != nil {
+		applyCORSHeaders(w, r, h.cfg.corsAllowedOrigin)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	isText := strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebTextContentType)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if isText {
+		if body, err = base64.StdEncoding.DecodeString(string(body)); err != nil {
+			http.Error(w, "httpgrpc: invalid base64 grpc-web-text body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	msg, _, err := readGRPCWebFrame(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req httpgrpc.HTTPRequest
+	if err := proto.Unmarshal(msg, &req); err != nil {
+		http.Error(w, "httpgrpc: invalid request message", http.StatusBadRequest)
+		return
+	}
+
+	resp, handlerErr := h.server.Handle(r.Context(), &req)
+	if handlerErr != nil {
+		var ok bool
+		if resp, ok = httpgrpc.HTTPResponseFromError(handlerErr); !ok {
+			resp = &httpgrpc.HTTPResponse{Code: http.StatusInternalServerError, Body: []byte(handlerErr.Error())}
+		}
+	}
+
+	respBytes, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := grpcWebContentType
+	if isText {
+		contentType = grpcWebTextContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	var out bytes.Buffer
+	writeGRPCWebFrame(&out, 0, respBytes)
+	writeGRPCWebFrame(&out, grpcWebTrailerFlag, encodeGRPCWebTrailers(grpcStatusOf(handlerErr)))
+
+	if isText {
+		_, _ = io.WriteString(w, base64.StdEncoding.EncodeToString(out.Bytes()))
+		return
+	}
+	_, _ = w.Write(out.Bytes())
+}
+
+// readGRPCWebFrame reads a single length-prefixed grpc-web frame off the
+// front of b and returns its payload and the number of bytes consumed.
+func readGRPCWebFrame(b []byte) ([]byte, int, error) {
+	if len(b) < 5 {
+		return nil, 0, fmt.Errorf("httpgrpc: grpc-web frame shorter than header")
+	}
+	length := binary.BigEndian.Uint32(b[1:5])
+	if uint32(len(b)) < 5+length {
+		return nil, 0, fmt.Errorf("httpgrpc: grpc-web frame length mismatch")
+	}
+	return b[5 : 5+length], 5 + int(length), nil
+}
+
+func writeGRPCWebFrame(buf *bytes.Buffer, flag byte, payload []byte) {
+	header := [5]byte{flag}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	buf.Write(header[:])
+	buf.Write(payload)
+}
+
+// encodeGRPCWebTrailers renders st as an HTTP/1.1-style header block, which
+// is how grpc-web carries trailers-as-headers in its trailer frame.
+func encodeGRPCWebTrailers(st *status.Status) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "grpc-status: %d\r\n", st.Code())
+	if msg := st.Message(); msg != "" {
+		fmt.Fprintf(&b, "grpc-message: %s\r\n", st.Message())
+	}
+	return []byte(b.String())
+}
+
+func grpcStatusOf(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	return status.Convert(err)
+}
+
+func applyCORSHeaders(w http.ResponseWriter, r *http.Request, allowed func(string) bool) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !allowed(origin) {
+		return
+	}
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Credentials", "true")
+	h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	h.Set("Access-Control-Allow-Headers", "Content-Type, X-Grpc-Web, X-User-Agent")
+	h.Set("Access-Control-Expose-Headers", "grpc-status, grpc-message")
+}
+
+// grpcWebTransport implements httpgrpc.HTTPClient over a plain HTTP client
+// speaking grpc-web framing, so WithGRPCWeb can plug it in behind the
+// regular Client/ServeHTTP code path.
+type grpcWebTransport struct {
+	httpClient *http.Client
+	url        string
+	asText     bool
+}
+
+func (t *grpcWebTransport) Handle(ctx context.Context, in *httpgrpc.HTTPRequest, _ ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+	payload, err := proto.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var framed bytes.Buffer
+	writeGRPCWebFrame(&framed, 0, payload)
+
+	body := framed.Bytes()
+	contentType := grpcWebContentType
+	if t.asText {
+		contentType = grpcWebTextContentType
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if t.asText {
+		if respBody, err = base64.StdEncoding.DecodeString(string(respBody)); err != nil {
+			return nil, fmt.Errorf("httpgrpc: invalid base64 grpc-web-text response: %w", err)
+		}
+	}
+
+	// A trailer frame may follow the message frame with grpc-status and
+	// grpc-message, but the HTTP status code already reflects the outcome
+	// for non-5xx responses, so it's not needed here.
+	msg, _, err := readGRPCWebFrame(respBody)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp := &httpgrpc.HTTPResponse{}
+	if err := proto.Unmarshal(msg, httpResp); err != nil {
+		return nil, fmt.Errorf("httpgrpc: invalid response message: %w", err)
+	}
+
+	if httpResp.Code/100 == 5 {
+		return nil, httpgrpc.ErrorFromHTTPResponse(httpResp)
+	}
+
+	return httpResp, nil
+}
+
+func (t *grpcWebTransport) HandleStream(context.Context, ...grpc.CallOption) (httpgrpc.HTTP_HandleStreamClient, error) {
+	return nil, fmt.Errorf("httpgrpc: streaming is not supported over grpc-web")
+}
+
+// newGRPCWebClient builds a Client that speaks grpc-web over HTTP instead of
+// dialling a gRPC connection. Most ClientOptions configure pieces of the
+// regular gRPC dial path (interceptors, OTel, resolvers, keepalive, message
+// size limits...) that have no equivalent here, so combining any of them
+// with WithGRPCWeb/WithGRPCWebText is rejected rather than silently
+// ignored. TLS is the exception: it's applied to httpClient's Transport, as
+// long as the caller didn't already supply their own http.Client for
+// WithGRPCWeb/WithGRPCWebText to use as-is.
+func newGRPCWebClient(address string, cfg *clientConfig) (*Client, error) {
+	if unsupported := unsupportedGRPCWebOptions(cfg); len(unsupported) > 0 {
+		return nil, fmt.Errorf("httpgrpc: %s not supported together with WithGRPCWeb/WithGRPCWebText", strings.Join(unsupported, ", "))
+	}
+
+	httpClient := cfg.httpClient
+	if httpClient == nil {
+		tlsConfig, err := cfg.tlsConfigOrNil()
+		if err != nil {
+			return nil, err
+		}
+		httpClient = http.DefaultClient
+		if tlsConfig != nil {
+			httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		}
+	} else if cfg.tlsConfig != nil || cfg.certFile != "" || cfg.keyFile != "" || cfg.caFile != "" {
+		return nil, fmt.Errorf("httpgrpc: WithTLSConfig/WithClientCertFile/WithClientKeyFile/WithCAFile can't be combined with an explicit http.Client passed to WithGRPCWeb/WithGRPCWebText; configure TLS on that client's Transport instead")
+	}
+
+	return &Client{
+		client: &grpcWebTransport{
+			httpClient: httpClient,
+			url:        address,
+			asText:     cfg.grpcWebText,
+		},
+		streamThreshold: cfg.streamThreshold,
+	}, nil
+}
+
+// unsupportedGRPCWebOptions returns the name of every ClientOption set on
+// cfg that newGRPCWebClient has no way to honor.
+func unsupportedGRPCWebOptions(cfg *clientConfig) []string {
+	var unsupported []string
+	if len(cfg.unaryInterceptors) > 0 {
+		unsupported = append(unsupported, "WithUnaryInterceptors")
+	}
+	if cfg.detailRegistry != nil {
+		unsupported = append(unsupported, "WithDetailRegistry")
+	}
+	if cfg.otelTracerProvider != nil {
+		unsupported = append(unsupported, "WithOpenTelemetryTracing")
+	}
+	if cfg.otelMeterProvider != nil {
+		unsupported = append(unsupported, "WithOpenTelemetryMeterProvider")
+	}
+	if cfg.maxRecvMsgSize > 0 {
+		unsupported = append(unsupported, "WithMaxCallRecvMsgSize")
+	}
+	if cfg.keepaliveParams != nil {
+		unsupported = append(unsupported, "WithKeepaliveParams")
+	}
+	if len(cfg.dialOptions) > 0 {
+		unsupported = append(unsupported, "WithDialOptions")
+	}
+	if len(cfg.customResolvers) > 0 {
+		unsupported = append(unsupported, "WithResolver")
+	}
+	if cfg.loadBalancingPolicy != newClientConfig().loadBalancingPolicy {
+		unsupported = append(unsupported, "WithLoadBalancingPolicy")
+	}
+	if cfg.resolverRefreshInterval != 0 {
+		unsupported = append(unsupported, "WithResolverRefreshInterval")
+	}
+	if cfg.consulAddress != "" {
+		unsupported = append(unsupported, "WithConsulAddress")
+	}
+	if cfg.consulToken != "" {
+		unsupported = append(unsupported, "WithConsulToken")
+	}
+	return unsupported
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestGRPCWebFrameRoundTrip(t *testing.T) {
+	for _, payload := range [][]byte{nil, []byte("hello"), bytes.Repeat([]byte{0xAB}, 1024)} {
+		var buf bytes.Buffer
+		writeGRPCWebFrame(&buf, 0, payload)
+
+		got, consumed, err := readGRPCWebFrame(buf.Bytes())
+		if err != nil {
+			t.Fatalf("readGRPCWebFrame: %v", err)
+		}
+		if consumed != buf.Len() {
+			t.Errorf("consumed = %d, want %d", consumed, buf.Len())
+		}
+		if !bytes.Equal(got, payload) {
+			t.Errorf("got payload %v, want %v", got, payload)
+		}
+	}
+}
+
+func TestReadGRPCWebFrameErrors(t *testing.T) {
+	if _, _, err := readGRPCWebFrame([]byte{0, 0, 0}); err == nil {
+		t.Error("expected error for frame shorter than header")
+	}
+
+	var buf bytes.Buffer
+	writeGRPCWebFrame(&buf, 0, []byte("hello"))
+	if _, _, err := readGRPCWebFrame(buf.Bytes()[:len(buf.Bytes())-1]); err == nil {
+		t.Error("expected error for truncated frame")
+	}
+}
+
+func TestUnsupportedGRPCWebOptions(t *testing.T) {
+	if got := unsupportedGRPCWebOptions(newClientConfig()); len(got) != 0 {
+		t.Errorf("default config reported unsupported options: %v", got)
+	}
+
+	cfg := newClientConfig()
+	WithUnaryInterceptors(nil)(cfg)
+	WithMaxCallRecvMsgSize(1024)(cfg)
+	got := unsupportedGRPCWebOptions(cfg)
+	want := []string{"WithUnaryInterceptors", "WithMaxCallRecvMsgSize"}
+	if len(got) != len(want) {
+		t.Fatalf("unsupportedGRPCWebOptions = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unsupportedGRPCWebOptions[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewGRPCWebClientRejectsIncompatibleOptions(t *testing.T) {
+	cfg := newClientConfig()
+	WithDetailRegistry(NewDetailRegistry())(cfg)
+	if _, err := newGRPCWebClient("example.com", cfg); err == nil {
+		t.Error("expected error combining WithDetailRegistry with grpc-web")
+	}
+}
+
+func TestNewGRPCWebClientRejectsTLSWithExplicitHTTPClient(t *testing.T) {
+	cfg := newClientConfig()
+	cfg.httpClient = http.DefaultClient
+	WithCAFile("/does/not/matter")(cfg)
+	if _, err := newGRPCWebClient("example.com", cfg); err == nil {
+		t.Error("expected error combining TLS options with an explicit http.Client")
+	}
+}
+
+func TestNewGRPCWebClientUsesDefaultClientWithoutOptions(t *testing.T) {
+	cfg := newClientConfig()
+	c, err := newGRPCWebClient("example.com", cfg)
+	if err != nil {
+		t.Fatalf("newGRPCWebClient: %v", err)
+	}
+	transport, ok := c.client.(*grpcWebTransport)
+	if !ok {
+		t.Fatalf("client.client is %T, want *grpcWebTransport", c.client)
+	}
+	if transport.httpClient != http.DefaultClient {
+		t.Errorf("expected http.DefaultClient to be used when no options are set")
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// clientConfig accumulates the settings applied by ClientOptions before the
+// gRPC connection is dialled.
+type clientConfig struct {
+	tlsConfig *tls.Config
+	certFile  string
+	keyFile   string
+	caFile    string
+
+	loadBalancingPolicy string
+	maxRecvMsgSize      int
+	keepaliveParams     *keepalive.ClientParameters
+
+	dialOptions       []grpc.DialOption
+	unaryInterceptors []grpc.UnaryClientInterceptor
+
+	streamThreshold int
+
+	otelTracerProvider  trace.TracerProvider
+	otelMeterProvider   metric.MeterProvider
+	openTracingDisabled bool
+
+	detailRegistry *DetailRegistry
+
+	grpcWeb     bool
+	grpcWebText bool
+	httpClient  *http.Client
+
+	resolverRefreshInterval time.Duration
+	consulAddress           string
+	consulToken             string
+	customResolvers         []namedResolverBuilder
+}
+
+func newClientConfig() *clientConfig {
+	return &clientConfig{
+		loadBalancingPolicy: "round_robin",
+		streamThreshold:     defaultStreamThreshold,
+	}
+}
+
+// tlsConfigOrNil builds the *tls.Config implied by the TLS-related options, or nil if
+// none of them were set, in which case the client dials insecurely.
+func (c *clientConfig) tlsConfigOrNil() (*tls.Config, error) {
+	if c.tlsConfig == nil && c.certFile == "" && c.keyFile == "" && c.caFile == "" {
+		return nil, nil
+	}
+
+	cfg := c.tlsConfig
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+
+	if c.certFile != "" || c.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpgrpc: loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.caFile != "" {
+		pem, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpgrpc: reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("httpgrpc: no certificates found in %s", c.caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*clientConfig)
+
+// WithTLSConfig dials using the given TLS config instead of insecure
+// credentials. Combine with WithClientCertFile/WithClientKeyFile or
+// WithCAFile to layer certificate material onto it.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *clientConfig) { c.tlsConfig = cfg }
+}
+
+// WithClientCertFile sets the client certificate file used for mTLS. It must
+// be paired with WithClientKeyFile.
+func WithClientCertFile(path string) ClientOption {
+	return func(c *clientConfig) { c.certFile = path }
+}
+
+// WithClientKeyFile sets the client private key file used for mTLS. It must
+// be paired with WithClientCertFile.
+func WithClientKeyFile(path string) ClientOption {
+	return func(c *clientConfig) { c.keyFile = path }
+}
+
+// WithCAFile adds the PEM-encoded certificates in path to the pool used to
+// verify the server's certificate, enabling TLS even without a client
+// certificate.
+func WithCAFile(path string) ClientOption {
+	return func(c *clientConfig) { c.caFile = path }
+}
+
+// WithDialOptions appends extra grpc.DialOptions, applied after dskit's own
+// defaults so callers can override anything they need to.
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(c *clientConfig) { c.dialOptions = append(c.dialOptions, opts...) }
+}
+
+// WithUnaryInterceptors appends extra unary client interceptors, chained
+// after the opentracing and user-header interceptors dskit installs by
+// default.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) ClientOption {
+	return func(c *clientConfig) { c.unaryInterceptors = append(c.unaryInterceptors, interceptors...) }
+}
+
+// WithMaxCallRecvMsgSize sets the maximum message size the client will
+// accept on received responses.
+func WithMaxCallRecvMsgSize(bytes int) ClientOption {
+	return func(c *clientConfig) { c.maxRecvMsgSize = bytes }
+}
+
+// WithLoadBalancingPolicy overrides the default "round_robin" load balancing
+// policy advertised in the client's default service config.
+func WithLoadBalancingPolicy(policy string) ClientOption {
+	return func(c *clientConfig) { c.loadBalancingPolicy = policy }
+}
+
+// WithKeepaliveParams configures gRPC keepalive pings on the client
+// connection.
+func WithKeepaliveParams(kp keepalive.ClientParameters) ClientOption {
+	return func(c *clientConfig) { c.keepaliveParams = &kp }
+}
+
+// WithStreamThreshold overrides the request body size above which ServeHTTP
+// switches from the unary Handle RPC to the streaming HandleStream RPC.
+func WithStreamThreshold(bytes int) ClientOption {
+	return func(c *clientConfig) { c.streamThreshold = bytes }
+}
+
+// WithDetailRegistry makes the client unpack the given custom detail proto
+// types from gRPC Status details, in addition to the well-known ones.
+func WithDetailRegistry(registry *DetailRegistry) ClientOption {
+	return func(c *clientConfig) { c.detailRegistry = registry }
+}
+
+// WithGRPCWeb makes NewClient dial address as an application/grpc-web
+// endpoint over plain HTTP (HTTP/1.1 or HTTP/2) instead of opening a gRPC
+// connection, for reaching services behind CDNs or ingresses that don't
+// support HTTP/2 trailers. A non-nil httpClient is used as-is, so TLS and
+// proxy settings are entirely up to its Transport. With nil, http.DefaultClient
+// is used, or, when TLS options are also given, a new client whose Transport
+// uses that TLS config.
+func WithGRPCWeb(httpClient *http.Client) ClientOption {
+	return func(c *clientConfig) {
+		c.grpcWeb = true
+		c.httpClient = httpClient
+	}
+}
+
+// WithGRPCWebText additionally base64-encodes grpc-web frames
+// (application/grpc-web-text), for environments that can't pass binary
+// bodies through untouched. Implies WithGRPCWeb.
+func WithGRPCWebText(httpClient *http.Client) ClientOption {
+	return func(c *clientConfig) {
+		c.grpcWeb = true
+		c.grpcWebText = true
+		c.httpClient = httpClient
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import "testing"
+
+func TestTLSConfigOrNilDefaultsToNil(t *testing.T) {
+	cfg := newClientConfig()
+	tlsConfig, err := cfg.tlsConfigOrNil()
+	if err != nil {
+		t.Fatalf("tlsConfigOrNil: %v", err)
+	}
+	if tlsConfig != nil {
+		t.Errorf("expected nil TLS config when no TLS options are set, got %+v", tlsConfig)
+	}
+}
+
+func TestTLSConfigOrNilMissingCertFile(t *testing.T) {
+	cfg := newClientConfig()
+	WithClientCertFile("/does/not/exist.crt")(cfg)
+	WithClientKeyFile("/does/not/exist.key")(cfg)
+	if _, err := cfg.tlsConfigOrNil(); err == nil {
+		t.Error("expected error loading a client certificate that doesn't exist")
+	}
+}
+
+func TestTLSConfigOrNilMissingCAFile(t *testing.T) {
+	cfg := newClientConfig()
+	WithCAFile("/does/not/exist.pem")(cfg)
+	if _, err := cfg.tlsConfigOrNil(); err == nil {
+		t.Error("expected error reading a CA file that doesn't exist")
+	}
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// WithOpenTelemetryTracing installs otelgrpc client interceptors using the
+// given TracerProvider. If this option isn't given, NewClient falls back to
+// otel.GetTracerProvider() when a non-default TracerProvider has been
+// registered globally, so most callers never need to set this explicitly.
+func WithOpenTelemetryTracing(tp trace.TracerProvider) ClientOption {
+	return func(c *clientConfig) { c.otelTracerProvider = tp }
+}
+
+// WithOpenTelemetryMeterProvider installs per-call metrics (request count,
+// duration, in-flight count and body size) using the given MeterProvider.
+func WithOpenTelemetryMeterProvider(mp metric.MeterProvider) ClientOption {
+	return func(c *clientConfig) { c.otelMeterProvider = mp }
+}
+
+// WithoutOpenTracing disables the default OpenTracing client interceptor,
+// e.g. when a caller has fully migrated to OpenTelemetry.
+func WithoutOpenTracing() ClientOption {
+	return func(c *clientConfig) { c.openTracingDisabled = true }
+}
+
+// otelTracerProviderConfigured reports whether a non-default TracerProvider
+// has been registered with the global otel package, used to auto-detect
+// whether to instrument gRPC calls with otelgrpc.
+func otelTracerProviderConfigured() bool {
+	// Until SetTracerProvider is called, otel returns its internal delegating
+	// *global.tracerProvider.
+	tpType := fmt.Sprintf("%T", otel.GetTracerProvider())
+	return tpType != "*global.tracerProvider" && !strings.Contains(tpType, "noop")
+}
+
+// resolveTracerProvider returns the TracerProvider that should be used for
+// otelgrpc instrumentation, or nil if OpenTelemetry tracing isn't in use.
+func resolveTracerProvider(cfg *clientConfig) trace.TracerProvider {
+	if cfg.otelTracerProvider != nil {
+		return cfg.otelTracerProvider
+	}
+	if otelTracerProviderConfigured() {
+		return otel.GetTracerProvider()
+	}
+	return nil
+}
+
+// clientMetrics holds the OTel instruments used to record per-call metrics on
+// the client side of an httpgrpc connection.
+type clientMetrics struct {
+	requests     metric.Int64Counter
+	duration     metric.Float64Histogram
+	inFlight     metric.Int64UpDownCounter
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+func newClientMetrics(mp metric.MeterProvider) (*clientMetrics, error) {
+	meter := mp.Meter("github.com/grafana/dskit/httpgrpc/server")
+
+	requests, err := meter.Int64Counter("httpgrpc_client_requests_total",
+		metric.WithDescription("Total number of httpgrpc client requests."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("httpgrpc_client_request_duration_seconds",
+		metric.WithDescription("Duration of httpgrpc client requests."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	inFlight, err := meter.Int64UpDownCounter("httpgrpc_client_in_flight_requests",
+		metric.WithDescription("Number of in-flight httpgrpc client requests."))
+	if err != nil {
+		return nil, err
+	}
+	requestSize, err := meter.Int64Histogram("httpgrpc_client_request_body_size_bytes",
+		metric.WithDescription("Size of httpgrpc client request bodies."),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+	responseSize, err := meter.Int64Histogram("httpgrpc_client_response_body_size_bytes",
+		metric.WithDescription("Size of httpgrpc client response bodies."),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientMetrics{
+		requests:     requests,
+		duration:     duration,
+		inFlight:     inFlight,
+		requestSize:  requestSize,
+		responseSize: responseSize,
+	}, nil
+}
+
+// unaryInterceptor returns a grpc.UnaryClientInterceptor that records the
+// configured instruments around a single Handle call.
+func (m *clientMetrics) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		httpMethod := ""
+		if r, ok := req.(interface{ GetMethod() string }); ok {
+			httpMethod = r.GetMethod()
+		}
+
+		attrs := []attribute.KeyValue{attribute.String("http.method", httpMethod)}
+		m.inFlight.Add(ctx, 1, metric.WithAttributes(attrs...))
+		defer m.inFlight.Add(ctx, -1, metric.WithAttributes(attrs...))
+
+		if r, ok := req.(interface{ GetBody() []byte }); ok {
+			m.requestSize.Record(ctx, int64(len(r.GetBody())), metric.WithAttributes(attrs...))
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		elapsed := time.Since(start).Seconds()
+
+		codeAttrs := append(attrs, attribute.String("grpc.code", status.Code(err).String()))
+		if resp, ok := reply.(interface{ GetCode() int32 }); ok {
+			codeAttrs = append(codeAttrs, attribute.String("http.status_class", statusClass(int(resp.GetCode()))))
+		}
+		if resp, ok := reply.(interface{ GetBody() []byte }); ok {
+			m.responseSize.Record(ctx, int64(len(resp.GetBody())), metric.WithAttributes(codeAttrs...))
+		}
+
+		m.requests.Add(ctx, 1, metric.WithAttributes(codeAttrs...))
+		m.duration.Record(ctx, elapsed, metric.WithAttributes(codeAttrs...))
+
+		return err
+	}
+}
+
+func statusClass(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// injectTraceContext writes the request's trace context and baggage into its
+// headers using the global OTel propagator, so they survive the trip through
+// HTTPRequest.Headers to the server side.
+func injectTraceContext(r *http.Request) {
+	otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+}
+
+// extractTraceContext reads trace context and baggage out of an unwrapped
+// request's headers and returns a context carrying them, for use in place of
+// the plain RPC context.
+func extractTraceContext(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// otelgrpcDialOptions builds the dial options needed to install otelgrpc
+// instrumentation, if a TracerProvider is in play.
+func otelgrpcDialOptions(cfg *clientConfig) []grpc.DialOption {
+	tp := resolveTracerProvider(cfg)
+	if tp == nil {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+	}
+}
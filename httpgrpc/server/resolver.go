@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+)
+
+// defaultResolverRefreshInterval is how often the dnspoll, srv+dns, consul and
+// file resolvers below re-resolve their target in the background, absent a
+// WithResolverRefreshInterval override.
+const defaultResolverRefreshInterval = 30 * time.Second
+
+// WithResolverRefreshInterval sets how often the built-in dnspoll, srv+dns,
+// consul and file resolvers re-resolve their target in the background.
+func WithResolverRefreshInterval(d time.Duration) ClientOption {
+	return func(c *clientConfig) { c.resolverRefreshInterval = d }
+}
+
+// WithConsulAddress sets the address (host:port) of the Consul agent the
+// consul:// resolver queries. Defaults to 127.0.0.1:8500.
+func WithConsulAddress(addr string) ClientOption {
+	return func(c *clientConfig) { c.consulAddress = addr }
+}
+
+// WithConsulToken sets the ACL token used to authenticate to Consul.
+func WithConsulToken(token string) ClientOption {
+	return func(c *clientConfig) { c.consulToken = token }
+}
+
+// WithResolver registers an additional gRPC name resolver under the given
+// scheme, scoped to the Client being built, so callers can plug in their own
+// service discovery without forking this package or polluting the global
+// resolver.Register registry.
+func WithResolver(scheme string, builder resolver.Builder) ClientOption {
+	return func(c *clientConfig) {
+		c.customResolvers = append(c.customResolvers, namedResolverBuilder{scheme: scheme, Builder: builder})
+	}
+}
+
+// namedResolverBuilder overrides Scheme() so a resolver.Builder supplied to
+// WithResolver is registered under the caller's chosen scheme regardless of
+// what it reports itself.
+type namedResolverBuilder struct {
+	scheme string
+	resolver.Builder
+}
+
+func (b namedResolverBuilder) Scheme() string { return b.scheme }
+
+// builtinResolvers returns the dnspoll, srv+dns, consul and file resolver
+// builders configured from cfg, for passing to grpc.WithResolvers. They are
+// built per-Client, not registered globally, so two Clients can use
+// different refresh intervals or Consul endpoints without colliding.
+func builtinResolvers(cfg *clientConfig) []resolver.Builder {
+	refresh := cfg.resolverRefreshInterval
+	if refresh <= 0 {
+		refresh = defaultResolverRefreshInterval
+	}
+
+	consulAddr := cfg.consulAddress
+	if consulAddr == "" {
+		consulAddr = "127.0.0.1:8500"
+	}
+
+	builders := []resolver.Builder{
+		&dnsResolverBuilder{refreshInterval: refresh},
+		&srvResolverBuilder{refreshInterval: refresh},
+		&consulResolverBuilder{address: consulAddr, token: cfg.consulToken, refreshInterval: refresh},
+		&fileResolverBuilder{refreshInterval: refresh},
+	}
+	for _, r := range cfg.customResolvers {
+		builders = append(builders, r)
+	}
+	return builders
+}
+
+// pollingResolver is the shared implementation behind the dnspoll, srv+dns,
+// consul and file resolvers: it calls lookup immediately and then on every
+// tick of refreshInterval, pushing whatever addresses it returns to cc.
+type pollingResolver struct {
+	cc              resolver.ClientConn
+	lookup          func() ([]resolver.Address, error)
+	refreshInterval time.Duration
+	cancel          context.CancelFunc
+}
+
+func startPollingResolver(cc resolver.ClientConn, refreshInterval time.Duration, lookup func() ([]resolver.Address, error)) *pollingResolver {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &pollingResolver{cc: cc, lookup: lookup, refreshInterval: refreshInterval, cancel: cancel}
+	go r.run(ctx)
+	return r
+}
+
+func (r *pollingResolver) run(ctx context.Context) {
+	r.resolveOnce()
+
+	ticker := time.NewTicker(r.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.resolveOnce()
+		}
+	}
+}
+
+func (r *pollingResolver) resolveOnce() {
+	addrs, err := r.lookup()
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// ResolveNow is a no-op: addresses are refreshed on a timer rather than on
+// demand, since DNS/Consul/file lookups are too slow to do inline with every
+// RPC that hits a transient error.
+func (r *pollingResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *pollingResolver) Close() { r.cancel() }
+
+// dnsResolverBuilder resolves dnspoll:///host:port by periodically
+// re-running a plain A/AAAA lookup. It's registered under its own scheme
+// rather than "dns" so it doesn't shadow grpc-go's builtin DNS resolver,
+// which every dns:///-targeted caller already gets for free and which does
+// a better job of it (backoff and ResolveNow-driven re-resolution instead of
+// a bare ticker). Use this one explicitly when a fixed refresh interval is
+// preferable to grpc-go's built-in behaviour.
+type dnsResolverBuilder struct {
+	refreshInterval time.Duration
+}
+
+func (*dnsResolverBuilder) Scheme() string { return "dnspoll" }
+
+func (b *dnsResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	host, port, err := net.SplitHostPort(target.Endpoint())
+	if err != nil {
+		return nil, fmt.Errorf("httpgrpc: dns resolver target %q must be host:port: %w", target.Endpoint(), err)
+	}
+
+	lookup := func() ([]resolver.Address, error) {
+		ips, err := net.DefaultResolver.LookupHost(context.Background(), host)
+		if err != nil {
+			return nil, err
+		}
+		addrs := make([]resolver.Address, 0, len(ips))
+		for _, ip := range ips {
+			addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(ip, port)})
+		}
+		return addrs, nil
+	}
+
+	return startPollingResolver(cc, b.refreshInterval, lookup), nil
+}
+
+// srvResolverBuilder resolves srv+dns://_service._proto.domain by looking up
+// the SRV record and dialing whatever targets/ports/weights it returns.
+type srvResolverBuilder struct {
+	refreshInterval time.Duration
+}
+
+func (*srvResolverBuilder) Scheme() string { return "srv+dns" }
+
+func (b *srvResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := target.Endpoint()
+	if name == "" {
+		// srv+dns://_service._proto.domain puts the name in the authority.
+		name = target.URL.Host
+	}
+
+	lookup := func() ([]resolver.Address, error) {
+		_, srvs, err := net.DefaultResolver.LookupSRV(context.Background(), "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		addrs := make([]resolver.Address, 0, len(srvs))
+		for _, srv := range srvs {
+			addrs = append(addrs, resolver.Address{
+				Addr:       net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))),
+				Attributes: attributesWithWeight(srv.Weight),
+			})
+		}
+		return addrs, nil
+	}
+
+	return startPollingResolver(cc, b.refreshInterval, lookup), nil
+}
+
+// consulResolverBuilder resolves consul://service-name against the Consul
+// HTTP API's health endpoint, filtered to passing instances.
+type consulResolverBuilder struct {
+	address         string
+	token           string
+	refreshInterval time.Duration
+}
+
+func (*consulResolverBuilder) Scheme() string { return "consul" }
+
+type consulHealthEntry struct {
+	Service struct {
+		Address string
+		Port    int
+		Weights struct {
+			Passing int
+		}
+	}
+}
+
+func (b *consulResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	service := target.Endpoint()
+	if service == "" {
+		// consul://service-name puts the service name in the authority.
+		service = target.URL.Host
+	}
+
+	lookup := func() ([]resolver.Address, error) {
+		u := url.URL{
+			Scheme:   "http",
+			Host:     b.address,
+			Path:     "/v1/health/service/" + service,
+			RawQuery: "passing=true",
+		}
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if b.token != "" {
+			req.Header.Set("X-Consul-Token", b.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("httpgrpc: consul health query for %q failed with status %d", service, resp.StatusCode)
+		}
+
+		var entries []consulHealthEntry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			return nil, fmt.Errorf("httpgrpc: decoding consul health response: %w", err)
+		}
+
+		addrs := make([]resolver.Address, 0, len(entries))
+		for _, e := range entries {
+			addrs = append(addrs, resolver.Address{
+				Addr:       net.JoinHostPort(e.Service.Address, strconv.Itoa(e.Service.Port)),
+				Attributes: attributesWithWeight(uint16(e.Service.Weights.Passing)),
+			})
+		}
+		return addrs, nil
+	}
+
+	return startPollingResolver(cc, b.refreshInterval, lookup), nil
+}
+
+// fileResolverBuilder resolves file:///path/to/endpoints.json against a
+// hot-reloaded static list of addresses, read fresh on every refresh tick so
+// editing the file picks up without restarting the client.
+type fileResolverBuilder struct {
+	refreshInterval time.Duration
+}
+
+func (*fileResolverBuilder) Scheme() string { return "file" }
+
+type fileResolverEndpoints struct {
+	Addresses []string `json:"addresses"`
+}
+
+func (b *fileResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	path := target.Endpoint()
+	if target.URL.Path != "" {
+		path = target.URL.Path
+	}
+
+	lookup := func() ([]resolver.Address, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("httpgrpc: reading endpoints file %q: %w", path, err)
+		}
+		var endpoints fileResolverEndpoints
+		if err := json.Unmarshal(data, &endpoints); err != nil {
+			return nil, fmt.Errorf("httpgrpc: parsing endpoints file %q: %w", path, err)
+		}
+		addrs := make([]resolver.Address, 0, len(endpoints.Addresses))
+		for _, a := range endpoints.Addresses {
+			addrs = append(addrs, resolver.Address{Addr: a})
+		}
+		return addrs, nil
+	}
+
+	return startPollingResolver(cc, b.refreshInterval, lookup), nil
+}
+
+// attributesWithWeight stashes a resolver-reported weight onto an Address so
+// a weight-aware balancer policy can read it back; round_robin (the
+// package's default) ignores it.
+func attributesWithWeight(weight uint16) *attributes.Attributes {
+	return attributes.New(weightAttributeKey{}, weight)
+}
+
+type weightAttributeKey struct{}
@@ -20,6 +20,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/sercand/kuberesolver/v5"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/grafana/dskit/httpgrpc"
@@ -47,6 +48,26 @@ func NewServer(handler http.Handler) *Server {
 	}
 }
 
+// NewServerWithMiddleware makes a new Server whose handler is wrapped with
+// the given middleware, in order, before being served. This saves downstream
+// callers from re-wiring the same middleware chain by hand around Handle.
+func NewServerWithMiddleware(handler http.Handler, middlewares ...middleware.Interface) *Server {
+	return NewServer(middleware.Merge(middlewares...).Wrap(handler))
+}
+
+// NewGRPCServer builds a *grpc.Server with server registered as the HTTP
+// service, chaining ErrorDetailsUnaryServerInterceptor ahead of any
+// interceptors in opts so every error it returns carries the rich status
+// details ErrorDetailsUnaryClientInterceptor unpacks on the other end.
+func NewGRPCServer(server *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(ErrorDetailsUnaryServerInterceptor()),
+	}, opts...)
+	s := grpc.NewServer(opts...)
+	httpgrpc.RegisterHTTPServer(s, server)
+	return s
+}
+
 type nopCloser struct {
 	*bytes.Buffer
 }
@@ -83,12 +104,18 @@ func (s Server) Handle(ctx context.Context, r *httpgrpc.HTTPRequest) (*httpgrpc.
 
 // Client is a http.Handler that forwards the request over gRPC.
 type Client struct {
-	client httpgrpc.HTTPClient
-	conn   *grpc.ClientConn
+	client          httpgrpc.HTTPClient
+	conn            *grpc.ClientConn
+	streamThreshold int
 }
 
 // ParseURL deals with direct:// style URLs, as well as kubernetes:// urls.
 // For backwards compatibility it treats URLs without schemes as kubernetes://.
+// Any other scheme (srv+dns, consul, dns, file, or one registered via
+// WithResolver) is passed through unchanged: it's up to whichever
+// resolver.Builder grpc.WithResolvers registered for it at dial time to make
+// sense of the target, exactly as grpc.Dial itself does for resolvers
+// registered globally.
 func ParseURL(unparsed string) (string, error) {
 	// if it has :///, this is the kuberesolver v2 URL. Return it as it is.
 	if strings.Contains(unparsed, ":///") {
@@ -127,28 +154,71 @@ func ParseURL(unparsed string) (string, error) {
 		return address, nil
 
 	default:
-		return "", fmt.Errorf("unrecognised scheme: %s", parsed.Scheme)
+		return unparsed, nil
 	}
 }
 
-// NewClient makes a new Client, given a kubernetes service address.
-func NewClient(address string) (*Client, error) {
+// NewClient makes a new Client, given a kubernetes service address. By
+// default it dials insecurely, round-robins across endpoints and chains the
+// opentracing and user-header interceptors; pass ClientOptions to change any
+// of that, e.g. to enable TLS/mTLS or add extra interceptors.
+func NewClient(address string, opts ...ClientOption) (*Client, error) {
+	cfg := newClientConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.grpcWeb {
+		return newGRPCWebClient(address, cfg)
+	}
+
 	kuberesolver.RegisterInCluster()
 
 	address, err := ParseURL(address)
 	if err != nil {
 		return nil, err
 	}
-	const grpcServiceConfig = `{"loadBalancingPolicy":"round_robin"}`
+
+	creds := insecure.NewCredentials()
+	tlsConfig, err := cfg.tlsConfigOrNil()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	grpcServiceConfig := fmt.Sprintf(`{"loadBalancingPolicy":%q}`, cfg.loadBalancingPolicy)
+
+	unaryInterceptors := []grpc.UnaryClientInterceptor{}
+	if !cfg.openTracingDisabled {
+		unaryInterceptors = append(unaryInterceptors, otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()))
+	}
+	unaryInterceptors = append(unaryInterceptors, middleware.ClientUserHeaderInterceptor)
+	unaryInterceptors = append(unaryInterceptors, ErrorDetailsUnaryClientInterceptor(cfg.detailRegistry))
+	if cfg.otelMeterProvider != nil {
+		metrics, err := newClientMetrics(cfg.otelMeterProvider)
+		if err != nil {
+			return nil, err
+		}
+		unaryInterceptors = append(unaryInterceptors, metrics.unaryInterceptor())
+	}
+	unaryInterceptors = append(unaryInterceptors, cfg.unaryInterceptors...)
 
 	dialOptions := []grpc.DialOption{
 		grpc.WithDefaultServiceConfig(grpcServiceConfig),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithChainUnaryInterceptor(
-			otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer()),
-			middleware.ClientUserHeaderInterceptor,
-		),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithResolvers(builtinResolvers(cfg)...),
+	}
+	dialOptions = append(dialOptions, otelgrpcDialOptions(cfg)...)
+	if cfg.maxRecvMsgSize > 0 {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.maxRecvMsgSize)))
+	}
+	if cfg.keepaliveParams != nil {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*cfg.keepaliveParams))
 	}
+	dialOptions = append(dialOptions, cfg.dialOptions...)
 
 	conn, err := grpc.Dial(address, dialOptions...)
 	if err != nil {
@@ -156,8 +226,9 @@ func NewClient(address string) (*Client, error) {
 	}
 
 	return &Client{
-		client: httpgrpc.NewHTTPClient(conn),
-		conn:   conn,
+		client:          httpgrpc.NewHTTPClient(conn),
+		conn:            conn,
+		streamThreshold: cfg.streamThreshold,
 	}, nil
 }
 
@@ -182,7 +253,7 @@ func UnwrapHTTPRequest(ctx context.Context, r *httpgrpc.HTTPRequest) (*http.Requ
 		return nil, err
 	}
 	toHeader(r.Headers, req.Header)
-	req = req.WithContext(ctx)
+	req = req.WithContext(extractTraceContext(ctx, req.Header))
 	req.RequestURI = r.Url
 	req.ContentLength = int64(len(r.Body))
 	return req, nil
@@ -215,6 +286,12 @@ func (c *Client) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	injectTraceContext(r)
+
+	if r.ContentLength < 0 || r.ContentLength > int64(c.streamThreshold) {
+		c.serveHTTPStream(w, r)
+		return
+	}
 
 	req, err := WrapHTTPRequest(r)
 	if err != nil {
@@ -0,0 +1,31 @@
+package server
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"bare host treated as kubernetes", "svc.namespace.svc.cluster.local:8080", "kubernetes:///svc.namespace.svc.cluster.local:8080", false},
+		{"kubernetes scheme rewritten", "kubernetes://svc.namespace:8080", "kubernetes:///svc.namespace:8080", false},
+		{"direct scheme unwrapped to host", "direct://1.2.3.4:8080", "1.2.3.4:8080", false},
+		{"triple-slash target passed through", "kubernetes:///svc.namespace:8080", "kubernetes:///svc.namespace:8080", false},
+		{"srv+dns scheme passed through", "srv+dns://_grpc._tcp.svc.example.com", "srv+dns://_grpc._tcp.svc.example.com", false},
+		{"consul scheme passed through", "consul://my-service", "consul://my-service", false},
+		{"custom resolver scheme passed through", "eureka://my-service", "eureka://my-service", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseURL(c.in)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("ParseURL(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("ParseURL(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
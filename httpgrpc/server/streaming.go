@@ -0,0 +1,271 @@
+// Provenance-includes-location: https://github.com/weaveworks/common/blob/main/httpgrpc/server/server.go
+// Provenance-includes-license: Apache-2.0
+// Provenance-includes-copyright: Weaveworks Ltd.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+)
+
+// defaultStreamThreshold is the request body size above which Client.ServeHTTP
+// switches from the unary Handle RPC to the streaming HandleStream RPC, so that
+// large bodies (log shipping, remote-write, chunk uploads) don't have to be
+// buffered whole in memory and don't trip gRPC message-size limits.
+const defaultStreamThreshold = 1 << 20 // 1MiB
+
+// HandleStream implements the streaming half of HTTPServer. The first message
+// on the stream carries the request's method, URL and headers; subsequent
+// messages carry body chunks terminated by a chunk with Eof set. The response
+// is streamed back the same way: a metadata message first, then body chunks.
+func (s Server) HandleStream(stream httpgrpc.HTTP_HandleStreamServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetRequestMetadata()
+	if meta == nil {
+		return fmt.Errorf("httpgrpc: first stream message must carry request metadata")
+	}
+
+	pr, pw := io.Pipe()
+	go streamRequestBody(stream, pw)
+
+	req, err := http.NewRequest(meta.Method, meta.Url, pr)
+	if err != nil {
+		return err
+	}
+	toHeader(meta.Headers, req.Header)
+	req = req.WithContext(stream.Context())
+	req.RequestURI = meta.Url
+
+	rw := newStreamResponseWriter(stream)
+	s.handler.ServeHTTP(rw, req)
+	return rw.Close()
+}
+
+// streamRequestBody drains body chunks off stream into pw until it sees an Eof
+// chunk, the stream ends, or it hits an error.
+func streamRequestBody(stream httpgrpc.HTTP_HandleStreamServer, pw *io.PipeWriter) {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			_ = pw.Close()
+			return
+		}
+		if err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+		chunk := msg.GetChunk()
+		if chunk == nil {
+			_ = pw.CloseWithError(fmt.Errorf("httpgrpc: expected body chunk message"))
+			return
+		}
+		if len(chunk.Data) > 0 {
+			if _, err := pw.Write(chunk.Data); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		if chunk.Eof {
+			_ = pw.Close()
+			return
+		}
+	}
+}
+
+// streamResponseWriter implements http.ResponseWriter on top of a
+// HandleStream server stream, sending the status code and headers as the
+// first reply message and every Write as a body chunk. A 5xx response is
+// buffered instead and reported as a gRPC error from Close, the same way
+// Server.Handle reports one, instead of being streamed like a normal reply;
+// unlike Handle's bodies, these are expected to be small, so buffering them
+// doesn't undermine HandleStream's reason for existing.
+type streamResponseWriter struct {
+	stream      httpgrpc.HTTP_HandleStreamServer
+	header      http.Header
+	wroteHeader bool
+	code        int
+	errBody     bytes.Buffer
+}
+
+func newStreamResponseWriter(stream httpgrpc.HTTP_HandleStreamServer) *streamResponseWriter {
+	return &streamResponseWriter{stream: stream, header: http.Header{}}
+}
+
+func (w *streamResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamResponseWriter) isError() bool {
+	return w.code/100 == 5
+}
+
+func (w *streamResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.code = code
+	if w.isError() {
+		return
+	}
+	_ = w.stream.Send(&httpgrpc.HTTPStreamChunk{
+		Message: &httpgrpc.HTTPStreamChunk_ResponseMetadata{
+			ResponseMetadata: &httpgrpc.ResponseMetadata{
+				Code:    int32(code),
+				Headers: fromHeader(w.header),
+			},
+		},
+	})
+}
+
+func (w *streamResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.isError() {
+		return w.errBody.Write(p)
+	}
+	if err := w.stream.Send(&httpgrpc.HTTPStreamChunk{
+		Message: &httpgrpc.HTTPStreamChunk_Chunk{
+			Chunk: &httpgrpc.BodyChunk{Data: p},
+		},
+	}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes the terminating Eof chunk and returns nil, or, for a 5xx
+// response, returns it as a gRPC error instead (mirroring Server.Handle) so
+// it carries the same DoNotLogErrorHeaderKey and rich status-detail
+// behavior regardless of whether the caller went through Handle or
+// HandleStream. It must be called once the handler has finished writing the
+// response.
+func (w *streamResponseWriter) Close() error {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.isError() {
+		resp := &httpgrpc.HTTPResponse{
+			Code:    int32(w.code),
+			Headers: fromHeader(w.header),
+			Body:    w.errBody.Bytes(),
+		}
+		err := httpgrpc.ErrorFromHTTPResponse(resp)
+		if _, ok := w.header[DoNotLogErrorHeaderKey]; ok {
+			err = middleware.DoNotLogError{Err: err}
+		}
+		return err
+	}
+	return w.stream.Send(&httpgrpc.HTTPStreamChunk{
+		Message: &httpgrpc.HTTPStreamChunk_Chunk{
+			Chunk: &httpgrpc.BodyChunk{Eof: true},
+		},
+	})
+}
+
+// serveHTTPStream is the streaming counterpart to Client.ServeHTTP, used once
+// the request body is larger than streamThreshold (or of unknown size).
+func (c *Client) serveHTTPStream(w http.ResponseWriter, r *http.Request) {
+	stream, err := c.client.HandleStream(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := stream.Send(&httpgrpc.HTTPStreamChunk{
+		Message: &httpgrpc.HTTPStreamChunk_RequestMetadata{
+			RequestMetadata: &httpgrpc.RequestMetadata{
+				Method:  r.Method,
+				Url:     r.RequestURI,
+				Headers: fromHeader(r.Header),
+			},
+		},
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	go sendRequestBody(stream, r.Body)
+
+	first, err := stream.Recv()
+	if err != nil {
+		// A 5xx response arrives as a stream error rather than response
+		// metadata (see streamResponseWriter.Close), just as it does from
+		// the unary Handle RPC.
+		if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+			_ = WriteResponse(w, resp)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	meta := first.GetResponseMetadata()
+	if meta == nil {
+		http.Error(w, "httpgrpc: expected response metadata", http.StatusInternalServerError)
+		return
+	}
+	toHeader(meta.Headers, w.Header())
+	w.WriteHeader(int(meta.Code))
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			return
+		}
+		chunk := msg.GetChunk()
+		if chunk == nil {
+			continue
+		}
+		if len(chunk.Data) > 0 {
+			if _, err := w.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+		if chunk.Eof {
+			return
+		}
+	}
+}
+
+// sendRequestBody streams body in fixed-size chunks followed by a terminating
+// Eof chunk, and closes the send side of the stream once done.
+func sendRequestBody(stream httpgrpc.HTTP_HandleStreamClient, body io.ReadCloser) {
+	defer func() { _ = stream.CloseSend() }()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if sendErr := stream.Send(&httpgrpc.HTTPStreamChunk{
+				Message: &httpgrpc.HTTPStreamChunk_Chunk{
+					Chunk: &httpgrpc.BodyChunk{Data: data},
+				},
+			}); sendErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	_ = stream.Send(&httpgrpc.HTTPStreamChunk{
+		Message: &httpgrpc.HTTPStreamChunk_Chunk{
+			Chunk: &httpgrpc.BodyChunk{Eof: true},
+		},
+	})
+}